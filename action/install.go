@@ -0,0 +1,26 @@
+package action
+
+import (
+	"context"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/repo"
+)
+
+// Install checks out a project's vendor directory from an existing lockfile,
+// or directly from conf (the config file) when no lockfile is present yet --
+// the common bootstrap case for a fresh `glide install`. lock is nil to
+// signal that case; useDev mirrors the `--test` flag on `glide install` and
+// is only consulted on that fresh-checkout path (an existing lockfile
+// already has DevImports resolved or not, per the `--test` given to the
+// `up` that produced it). As with Update, a repo.MultiError from
+// Installer.Install or Installer.Checkout is reported as a summary and
+// either swallowed (installer.Force) or returned so the command fails hard.
+func Install(ctx context.Context, installer *repo.Installer, lock *cfg.Lockfile, conf *cfg.Config, useDev bool) (*cfg.Config, error) {
+	if lock == nil {
+		return conf, reportMultiError(installer, installer.Checkout(ctx, conf, useDev))
+	}
+
+	newConf, err := installer.Install(ctx, lock, conf)
+	return newConf, reportMultiError(installer, err)
+}
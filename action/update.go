@@ -0,0 +1,59 @@
+// Package action wires CLI commands to the underlying repo operations.
+package action
+
+import (
+	"context"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/glide/repo"
+)
+
+// Update updates a project's dependencies, checks them out into vendor, and
+// writes the resulting lockfile.
+//
+// resolveTest mirrors the `--test` flag on `glide up`: when true, conf.DevImports
+// are resolved alongside conf.Imports (via Installer.Update) and checked out
+// alongside conf.Imports (via Installer.Checkout), and recorded on
+// Lockfile.DevImports, so `glide up --test` produces a lockfile that includes
+// test-only transitive dependencies and a vendor directory with them checked
+// out. When false, dev imports are left unresolved and unchecked-out,
+// matching the historical `glide up` behavior.
+//
+// A repo.MultiError from either step is reported as a per-repo summary via
+// msg.Warn. With installer.Force set, Update swallows the MultiError and
+// returns the lockfile anyway; otherwise the MultiError is returned so the
+// caller fails hard.
+func Update(ctx context.Context, installer *repo.Installer, conf *cfg.Config, resolveTest bool) (*cfg.Lockfile, error) {
+	lock, err := installer.Update(ctx, conf, resolveTest)
+	if err := reportMultiError(installer, err); err != nil {
+		return lock, err
+	}
+
+	err = installer.Checkout(ctx, conf, resolveTest)
+	return lock, reportMultiError(installer, err)
+}
+
+// reportMultiError prints a per-repo summary when err is a *repo.MultiError,
+// then either swallows it (installer.Force) or passes it through so the
+// caller can fail hard.
+func reportMultiError(installer *repo.Installer, err error) error {
+	me, ok := err.(*repo.MultiError)
+	if !ok || me.Len() == 0 {
+		return err
+	}
+
+	msg.Warn("%d repositor%s failed:\n%s", me.Len(), plural(me.Len()), me.Error())
+	if installer.Force {
+		msg.Warn("Continuing anyway because --force was set.")
+		return nil
+	}
+	return err
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
@@ -0,0 +1,33 @@
+package cfg
+
+// Source identifiers select which Source backend (see the repo package)
+// materializes a Dependency: a full VCS checkout, a Go module proxy zip, or
+// a plain tarball/zip download. Dependency.SourceType is usually left empty
+// and auto-detected from Repository; set it explicitly to override that.
+const (
+	SourceVCS     = "vcs"
+	SourceGoProxy = "goproxy"
+	SourceTarball = "tarball"
+)
+
+// Dependency describes a package that the present package depends upon.
+type Dependency struct {
+	Name        string
+	Reference   string
+	Repository  string
+	VcsType     string
+	Subpackages []string
+	Arch        []string
+	Os          []string
+
+	// SourceType selects the Source backend used to fetch this dependency.
+	// One of the Source* constants, or empty to auto-detect from Repository.
+	SourceType string
+
+	// Hash is the content hash recorded for a dependency fetched through an
+	// immutable Source backend (SourceGoProxy, SourceTarball). When set, the
+	// backend verifies the fetched artifact against it instead of trusting
+	// the download; when unset, the Installer records the hash of a fresh
+	// fetch here. That recorded hash is not written back to glide.lock.
+	Hash string
+}
@@ -0,0 +1,86 @@
+package cfg
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LockFile is the name of the lockfile glide writes next to glide.yaml.
+const LockFile = "glide.lock"
+
+// LockedDependency is a single dependency entry recorded in a Lockfile.
+type LockedDependency struct {
+	Name        string   `yaml:"name"`
+	Version     string   `yaml:"version"`
+	Repository  string   `yaml:"repo,omitempty"`
+	VcsType     string   `yaml:"vcs,omitempty"`
+	Subpackages []string `yaml:"subpackages,omitempty"`
+	Arch        []string `yaml:"arch,omitempty"`
+	Os          []string `yaml:"os,omitempty"`
+
+	// SourceType is copied from Dependency.SourceType by NewLockfile, so a
+	// later Install reconstructs the same Source backend (see the repo
+	// package) instead of re-detecting it from Repository. It is empty in a
+	// lockfile written by Installer.Update.
+	SourceType string `yaml:"source,omitempty"`
+
+	// Hash is copied from Dependency.Hash by NewLockfile, so a later Install
+	// can verify a fresh download against it. It is empty in a lockfile
+	// written by Installer.Update.
+	Hash string `yaml:"hash,omitempty"`
+}
+
+// Lockfile represents a glide.lock file: the exact set of dependencies
+// (and, separately, dev dependencies) an Update resolved.
+type Lockfile struct {
+	Hash       string              `yaml:"hash"`
+	Imports    []*LockedDependency `yaml:"imports"`
+	DevImports []*LockedDependency `yaml:"devImports,omitempty"`
+}
+
+// NewLockfile builds a Lockfile recording deps, with hash as the overall
+// config hash used to detect when glide.yaml has drifted from glide.lock.
+func NewLockfile(deps []*Dependency, hash string) *Lockfile {
+	lock := &Lockfile{
+		Hash:    hash,
+		Imports: make([]*LockedDependency, len(deps)),
+	}
+	for i, d := range deps {
+		lock.Imports[i] = &LockedDependency{
+			Name:        d.Name,
+			Version:     d.Reference,
+			Repository:  d.Repository,
+			VcsType:     d.VcsType,
+			Subpackages: d.Subpackages,
+			Arch:        d.Arch,
+			Os:          d.Os,
+			SourceType:  d.SourceType,
+			Hash:        d.Hash,
+		}
+	}
+	return lock
+}
+
+// ReadLockFile reads and parses the lockfile at LockFile.
+func ReadLockFile() (*Lockfile, error) {
+	data, err := ioutil.ReadFile(LockFile)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lockfile{}
+	if err := yaml.Unmarshal(data, lock); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// WriteFile writes the lockfile as YAML to path.
+func (l *Lockfile) WriteFile(path string) error {
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/Masterminds/glide/action"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/glide/repo"
+	"github.com/urfave/cli"
+)
+
+// InstallCommand builds the `glide install` command.
+//
+// The `--test` flag only matters on a fresh install with no glide.lock yet:
+// it checks out conf.DevImports alongside conf.Imports. With a lockfile
+// present, the lockfile's own DevImports (or lack of them) wins.
+func InstallCommand() cli.Command {
+	return cli.Command{
+		Name:  "install",
+		Usage: "Install a project's dependencies",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "test",
+				Usage: "On a fresh install, also check out devImports",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			conf, err := cfg.ReadConfig()
+			if err != nil {
+				return err
+			}
+
+			lock, err := cfg.ReadLockFile()
+			if err != nil {
+				if !os.IsNotExist(err) {
+					return err
+				}
+				lock = nil
+			}
+
+			installer := &repo.Installer{
+				Force:    c.GlobalBool("force"),
+				Home:     c.GlobalString("home"),
+				UseCache: c.GlobalBool("cache"),
+			}
+
+			_, err = action.Install(context.Background(), installer, lock, conf, c.Bool("test"))
+			if err != nil {
+				msg.Die("Failed to install: %s", err)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/Masterminds/glide/action"
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/msg"
+	"github.com/Masterminds/glide/repo"
+	"github.com/urfave/cli"
+)
+
+// UpCommand builds the `glide up` command.
+//
+// The `--test` flag resolves conf.DevImports in addition to conf.Imports, so
+// the resulting lockfile includes test-only transitive dependencies. Without
+// it, `glide up` keeps its historical behavior of leaving dev imports
+// unresolved.
+func UpCommand() cli.Command {
+	return cli.Command{
+		Name:  "up",
+		Usage: "Update a project's dependencies",
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "test",
+				Usage: "Also resolve devImports and record them in the lockfile",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			conf, err := cfg.ReadConfig()
+			if err != nil {
+				return err
+			}
+
+			installer := &repo.Installer{
+				Force:    c.GlobalBool("force"),
+				Home:     c.GlobalString("home"),
+				UseCache: c.GlobalBool("cache"),
+			}
+
+			lock, err := action.Update(context.Background(), installer, conf, c.Bool("test"))
+			if err != nil {
+				msg.Die("Failed to update: %s", err)
+			}
+			return lock.WriteFile(cfg.LockFile)
+		},
+	}
+}
@@ -1,10 +1,10 @@
 package repo
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
 
 	"github.com/Masterminds/glide/cfg"
 	"github.com/Masterminds/glide/dependency"
@@ -35,10 +35,20 @@ type Installer struct {
 
 	// DeleteUnused deletes packages that are unused, but found in the vendor dir.
 	DeleteUnused bool
+
+	// MaxWorkers bounds how many dependencies are fetched or updated
+	// concurrently. A zero value falls back to concurrentWorkers.
+	MaxWorkers int
 }
 
 // Install installs the dependencies from a Lockfile.
-func (i *Installer) Install(lock *cfg.Lockfile, conf *cfg.Config) (*cfg.Config, error) {
+//
+// Reconstructed dependencies carry forward v.SourceType and v.Hash from the
+// lockfile, so Source.Fetch verifies a fresh download against v.Hash when the
+// lockfile has one. A lockfile written by Update has neither field set: Update
+// builds it from depsFromPackages, whose dependencies carry no SourceType or
+// Hash.
+func (i *Installer) Install(ctx context.Context, lock *cfg.Lockfile, conf *cfg.Config) (*cfg.Config, error) {
 
 	cwd, err := gpath.Vendor()
 	if err != nil {
@@ -60,6 +70,8 @@ func (i *Installer) Install(lock *cfg.Lockfile, conf *cfg.Config) (*cfg.Config,
 			Subpackages: v.Subpackages,
 			Arch:        v.Arch,
 			Os:          v.Os,
+			SourceType:  v.SourceType,
+			Hash:        v.Hash,
 		}
 	}
 
@@ -73,6 +85,8 @@ func (i *Installer) Install(lock *cfg.Lockfile, conf *cfg.Config) (*cfg.Config,
 			Subpackages: v.Subpackages,
 			Arch:        v.Arch,
 			Os:          v.Os,
+			SourceType:  v.SourceType,
+			Hash:        v.Hash,
 		}
 	}
 
@@ -83,48 +97,59 @@ func (i *Installer) Install(lock *cfg.Lockfile, conf *cfg.Config) (*cfg.Config,
 		return newConf, nil
 	}
 
-	ConcurrentUpdate(newConf.Imports, cwd, i)
-	ConcurrentUpdate(newConf.DevImports, cwd, i)
-	return newConf, nil
+	var errs MultiError
+	if me := i.checkoutImports(ctx, newConf.Imports, cwd); me.Len() > 0 {
+		errs.Errs = append(errs.Errs, me.Errs...)
+	}
+	if me := i.checkoutImports(ctx, newConf.DevImports, cwd); me.Len() > 0 {
+		errs.Errs = append(errs.Errs, me.Errs...)
+	}
+	return newConf, errs.ErrOrNil()
 }
 
 // Checkout reads the config file and checks out all dependencies mentioned there.
 //
 // This is used when initializing an empty vendor directory, or when updating a
 // vendor directory based on changed config.
-func (i *Installer) Checkout(conf *cfg.Config, useDev bool) error {
+func (i *Installer) Checkout(ctx context.Context, conf *cfg.Config, useDev bool) error {
 
 	// FIXME: This should not be hard-coded.
 	dest := "./vendor"
 
-	if err := i.checkoutImports(conf.Imports, dest); err != nil {
-		return err
+	var errs MultiError
+	if me := i.checkoutImports(ctx, conf.Imports, dest); me.Len() > 0 {
+		errs.Errs = append(errs.Errs, me.Errs...)
 	}
 
 	if useDev {
-		return i.checkoutImports(conf.DevImports, dest)
+		if me := i.checkoutImports(ctx, conf.DevImports, dest); me.Len() > 0 {
+			errs.Errs = append(errs.Errs, me.Errs...)
+		}
 	}
 
-	return nil
+	return errs.ErrOrNil()
 }
 
-func (i *Installer) checkoutImports(deps []*cfg.Dependency, dest string) error {
-	for _, c := range deps {
+func (i *Installer) checkoutImports(ctx context.Context, deps []*cfg.Dependency, dest string) MultiError {
+	return runParallel(ctx, deps, i.MaxWorkers, func(ctx context.Context, c *cfg.Dependency) error {
 		if _, err := os.Stat(filepath.Join(dest, c.Name)); err == nil {
 			msg.Debug("Package %s already found.", c.Name)
-			continue
+			return nil
 		}
 
 		target := filepath.Join(dest, c.Name)
+		src := sourceFor(ctx, c, i.Home, i.UseCache, i.UseCacheGopath, i.UseGopath)
 
 		msg.Info("Fetching %s into %s", c.Name, dest)
-		if err := VcsGet(c, target, i.Home, i.UseCache, i.UseCacheGopath, i.UseGopath); err != nil {
-			// Should we try to get as many as we can and delay errors until the
-			// end?
+		if err := src.Fetch(c, target); err != nil {
 			return err
 		}
-	}
-	return nil
+
+		if _, hash, err := src.Resolve(c); err == nil && hash != "" {
+			c.Hash = hash
+		}
+		return nil
+	})
 }
 
 // Update updates all dependencies.
@@ -134,7 +159,11 @@ func (i *Installer) checkoutImports(deps []*cfg.Dependency, dest string) error {
 // listed, but the version reconciliation has not been done.
 //
 // In other words, all versions in the Lockfile will be empty.
-func (i *Installer) Update(conf *cfg.Config) (*cfg.Lockfile, error) {
+//
+// When resolveTest is true, conf.DevImports are resolved and updated as well,
+// with the results recorded on Lockfile.DevImports rather than mixed into
+// Lockfile.Imports.
+func (i *Installer) Update(ctx context.Context, conf *cfg.Config, resolveTest bool) (*cfg.Lockfile, error) {
 	base := "."
 
 	m := &MissingPackageHandler{
@@ -146,6 +175,7 @@ func (i *Installer) Update(conf *cfg.Config) (*cfg.Lockfile, error) {
 		cacheGopath: i.UseCacheGopath,
 		useGopath:   i.UseGopath,
 		home:        i.Home,
+		ctx:         ctx,
 	}
 
 	// Update imports
@@ -160,51 +190,62 @@ func (i *Installer) Update(conf *cfg.Config) (*cfg.Lockfile, error) {
 		msg.Die("Failed to retrieve a list of dependencies: %s", err)
 	}
 
-	msg.Warn("devImports not resolved.")
-
 	deps := depsFromPackages(packages)
-	ConcurrentUpdate(deps, base, i)
+	errs := ConcurrentUpdate(ctx, deps, base, i)
+
+	var devDeps []*cfg.Dependency
+	if resolveTest {
+		msg.Info("Resolving devImports")
+
+		// Resolve against the union of conf.Imports and conf.DevImports,
+		// not conf.DevImports alone: a dev-only import can transitively
+		// depend on a package that conf.Imports already requires, and
+		// resolving it in isolation would miss that shared subtree (or
+		// resolve it against a different version than the production
+		// import graph settled on).
+		union := make([]*cfg.Dependency, 0, len(conf.Imports)+len(conf.DevImports))
+		union = append(union, conf.Imports...)
+		union = append(union, conf.DevImports...)
+
+		devPackages, err := allPackages(union, res)
+		if err != nil {
+			msg.Die("Failed to retrieve a list of dev dependencies: %s", err)
+		}
+
+		devDeps = depsFromPackages(devPackages)
+		if me := ConcurrentUpdate(ctx, devDeps, base, i); me.Len() > 0 {
+			errs.Errs = append(errs.Errs, me.Errs...)
+		}
+	} else {
+		msg.Warn("devImports not resolved.")
+	}
 
 	hash, err := conf.Hash()
 	if err != nil {
 		return nil, err
 	}
-	return cfg.NewLockfile(deps, hash), nil
-}
-
-// ConcurrentUpdate takes a list of dependencies and updates in parallel.
-func ConcurrentUpdate(deps []*cfg.Dependency, cwd string, i *Installer) {
-	done := make(chan struct{}, concurrentWorkers)
-	in := make(chan *cfg.Dependency, concurrentWorkers)
-	var wg sync.WaitGroup
-
-	for ii := 0; ii < concurrentWorkers; ii++ {
-		go func(ch <-chan *cfg.Dependency) {
-			for {
-				select {
-				case dep := <-ch:
-					if err := VcsUpdate(dep, cwd, i); err != nil {
-						msg.Warn("Update failed for %s: %s\n", dep.Name, err)
-					}
-					wg.Done()
-				case <-done:
-					return
-				}
-			}
-		}(in)
-	}
 
-	for _, dep := range deps {
-		wg.Add(1)
-		in <- dep
+	lockfile := cfg.NewLockfile(deps, hash)
+	if resolveTest {
+		lockfile.DevImports = cfg.NewLockfile(devDeps, hash).Imports
 	}
+	return lockfile, errs.ErrOrNil()
+}
 
-	wg.Wait()
-
-	// Close goroutines setting the version
-	for ii := 0; ii < concurrentWorkers; ii++ {
-		done <- struct{}{}
-	}
+// ConcurrentUpdate takes a list of dependencies and updates in parallel,
+// aggregating any per-dependency failures into a MultiError rather than
+// dropping them after logging. Per-dependency failures are logged at Debug
+// level only; the caller (e.g. action.Update's reportMultiError) already
+// prints an aggregate summary from the returned MultiError, so logging them
+// at Warn here would print each failure twice.
+func ConcurrentUpdate(ctx context.Context, deps []*cfg.Dependency, cwd string, i *Installer) MultiError {
+	return runParallel(ctx, deps, i.MaxWorkers, func(ctx context.Context, dep *cfg.Dependency) error {
+		if err := VcsUpdate(ctx, dep, cwd, i); err != nil {
+			msg.Debug("Update failed for %s: %s\n", dep.Name, err)
+			return err
+		}
+		return nil
+	})
 }
 
 // allPackages gets a list of all packages required to satisfy the given deps.
@@ -286,13 +327,21 @@ type MissingPackageHandler struct {
 	destination                   string
 	home                          string
 	cache, cacheGopath, useGopath bool
+
+	// ctx is threaded through to the Source backend. NotFound's dependency
+	// has no SourceType or Repository, so that backend is vcsSource, which
+	// checks ctx only before a clone starts (see VcsGet); a clone already
+	// running is not cancelled. dependency.MissingPackageHandler's NotFound has no
+	// context parameter of its own, so it's carried on the struct instead.
+	ctx context.Context
 }
 
 func (m *MissingPackageHandler) NotFound(pkg string) (bool, error) {
 	msg.Info("Fetching %s into %s", pkg, m.destination)
 	d := &cfg.Dependency{Name: pkg}
 	dest := filepath.Join(m.destination, pkg)
-	if err := VcsGet(d, dest, m.home, m.cache, m.cacheGopath, m.useGopath); err != nil {
+	src := sourceFor(m.ctx, d, m.home, m.cache, m.cacheGopath, m.useGopath)
+	if err := src.Fetch(d, dest); err != nil {
 		return false, err
 	}
 	return true, nil
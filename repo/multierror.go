@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+// DependencyError pairs a dependency with the error encountered while
+// fetching or updating it.
+type DependencyError struct {
+	Dependency *cfg.Dependency
+	Error      error
+}
+
+// MultiError collects the errors encountered while processing a list of
+// dependencies, e.g. during ConcurrentUpdate or checkoutImports, so that a
+// single repo failure doesn't prevent callers from seeing the rest.
+type MultiError struct {
+	Errs []DependencyError
+}
+
+// Add appends a dependency/error pair to the MultiError.
+//
+// If err is nil, Add is a no-op, so callers can unconditionally pass through
+// whatever VcsGet or VcsUpdate returned.
+func (e *MultiError) Add(dep *cfg.Dependency, err error) {
+	if err == nil {
+		return
+	}
+	e.Errs = append(e.Errs, DependencyError{Dependency: dep, Error: err})
+}
+
+// Len returns the number of errors collected.
+func (e *MultiError) Len() int {
+	if e == nil {
+		return 0
+	}
+	return len(e.Errs)
+}
+
+// ErrOrNil returns the MultiError if it has collected any errors, or nil
+// otherwise. This lets callers return `me.ErrOrNil()` from a func(...) error
+// without an extra len check at every call site.
+func (e *MultiError) ErrOrNil() error {
+	if e.Len() == 0 {
+		return nil
+	}
+	return e
+}
+
+// Error joins the collected per-dependency errors into a single message.
+func (e *MultiError) Error() string {
+	var buf bytes.Buffer
+	for _, de := range e.Errs {
+		fmt.Fprintf(&buf, "%s: %s\n", de.Dependency.Name, de.Error)
+	}
+	return buf.String()
+}
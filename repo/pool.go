@@ -0,0 +1,101 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/glide/util"
+)
+
+// perHostLimit bounds how many in-flight operations a single host (e.g.
+// github.com) may have at once, regardless of how many total workers are
+// running, so a large tree doesn't hammer one Git server.
+const perHostLimit = 4
+
+// runParallel runs work for every dependency in deps, bounded to maxWorkers
+// concurrent goroutines in total (falling back to concurrentWorkers when
+// maxWorkers is unset) and perHostLimit per host, collecting any
+// per-dependency failures into a MultiError. Cancelling ctx stops queuing
+// new work; in-flight work is expected to observe ctx itself and return
+// promptly.
+func runParallel(ctx context.Context, deps []*cfg.Dependency, maxWorkers int, work func(context.Context, *cfg.Dependency) error) MultiError {
+	if maxWorkers <= 0 {
+		maxWorkers = concurrentWorkers
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		errs  MultiError
+		hosts = newHostSemaphores()
+		slots = make(chan struct{}, maxWorkers)
+	)
+
+	for _, dep := range deps {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			errs.Add(dep, ctx.Err())
+			mu.Unlock()
+			continue
+		default:
+		}
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(dep *cfg.Dependency) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			release := hosts.acquire(dep.Name)
+			defer release()
+
+			if err := work(ctx, dep); err != nil {
+				mu.Lock()
+				errs.Add(dep, err)
+				mu.Unlock()
+			}
+		}(dep)
+	}
+
+	wg.Wait()
+	return errs
+}
+
+// hostSemaphores hands out a bounded, per-host channel semaphore, creating
+// it on first use.
+type hostSemaphores struct {
+	mu   sync.Mutex
+	sems map[string]chan struct{}
+}
+
+func newHostSemaphores() *hostSemaphores {
+	return &hostSemaphores{sems: make(map[string]chan struct{})}
+}
+
+func (h *hostSemaphores) acquire(name string) func() {
+	host := hostOf(name)
+
+	h.mu.Lock()
+	sem, ok := h.sems[host]
+	if !ok {
+		sem = make(chan struct{}, perHostLimit)
+		h.sems[host] = sem
+	}
+	h.mu.Unlock()
+
+	sem <- struct{}{}
+	return func() { <-sem }
+}
+
+// hostOf extracts the host component (e.g. "github.com") from a package or
+// repo name, using the same root util.NormalizeName resolves.
+func hostOf(name string) string {
+	root, _ := util.NormalizeName(name)
+	if idx := strings.Index(root, "/"); idx > 0 {
+		return root[:idx]
+	}
+	return root
+}
@@ -0,0 +1,312 @@
+package repo
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+// Source materializes a single dependency on disk. VcsGet/VcsUpdate used to
+// be the only way to do this; Source lets a dependency be pinned to an
+// immutable artifact (a Go module proxy zip, or a plain tarball) instead of
+// requiring a full repository clone.
+type Source interface {
+	// Fetch places dep at dest, cloning, downloading, or unpacking it as
+	// appropriate for the backend.
+	Fetch(dep *cfg.Dependency, dest string) error
+
+	// Resolve reports the version actually fetched and, when the backend can
+	// produce one, a content hash that can later be used to verify dest
+	// without a network round-trip.
+	Resolve(dep *cfg.Dependency) (version, hash string, err error)
+}
+
+// sourceFor picks the Source implementation for dep based on
+// dep.SourceType, auto-detecting from the Repository URL when SourceType is
+// unset. ctx is passed to every backend: the HTTP backends abort an
+// in-flight download when it is cancelled, while vcsSource only checks it
+// before a clone starts (see VcsGet), so a clone already running is not
+// cancelled.
+func sourceFor(ctx context.Context, dep *cfg.Dependency, home string, cache, cacheGopath, useGopath bool) Source {
+	switch dep.SourceType {
+	case cfg.SourceGoProxy:
+		return &goProxySource{ctx: ctx}
+	case cfg.SourceTarball:
+		return &tarballSource{ctx: ctx}
+	case cfg.SourceVCS:
+		return &vcsSource{ctx: ctx, home: home, cache: cache, cacheGopath: cacheGopath, useGopath: useGopath}
+	}
+
+	// No explicit SourceType: auto-detect from the repository URL.
+	repository := strings.ToLower(dep.Repository)
+	switch {
+	case strings.HasSuffix(repository, ".tar.gz"), strings.HasSuffix(repository, ".tgz"), strings.HasSuffix(repository, ".zip"):
+		return &tarballSource{ctx: ctx}
+	default:
+		return &vcsSource{ctx: ctx, home: home, cache: cache, cacheGopath: cacheGopath, useGopath: useGopath}
+	}
+}
+
+// vcsSource is the original backend: a full git/hg/bzr/svn checkout.
+type vcsSource struct {
+	ctx                           context.Context
+	home                          string
+	cache, cacheGopath, useGopath bool
+}
+
+func (s *vcsSource) Fetch(dep *cfg.Dependency, dest string) error {
+	return VcsGet(s.ctx, dep, dest, s.home, s.cache, s.cacheGopath, s.useGopath)
+}
+
+func (s *vcsSource) Resolve(dep *cfg.Dependency) (string, string, error) {
+	// A VCS checkout is mutable by nature; there's no content hash to
+	// verify against, only the reference that was checked out.
+	return dep.Reference, "", nil
+}
+
+// goProxySource fetches an immutable module zip from a Go module proxy
+// (https://proxy.golang.org/<module>/@v/<version>.zip) and verifies it
+// against dep.Hash when that is set. The hash it computes and records is
+// "h1:" followed by the base64 SHA-256 of the zip file's bytes; it is not
+// the go.sum h1 directory hash, so a value taken from go.sum does not match.
+type goProxySource struct {
+	ctx context.Context
+
+	// ProxyURL overrides the default proxy, primarily for tests.
+	ProxyURL string
+
+	// hash is the content hash computed during Fetch, cached so Resolve
+	// doesn't need to re-download the artifact just to recompute it.
+	hash string
+}
+
+func (s *goProxySource) proxyURL() string {
+	if s.ProxyURL != "" {
+		return s.ProxyURL
+	}
+	return "https://proxy.golang.org"
+}
+
+func (s *goProxySource) Fetch(dep *cfg.Dependency, dest string) error {
+	if dep.Reference == "" {
+		return fmt.Errorf("cannot fetch %s from the module proxy without a pinned version", dep.Name)
+	}
+
+	zipURL := fmt.Sprintf("%s/%s/@v/%s.zip", s.proxyURL(), dep.Name, dep.Reference)
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, zipURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %s", zipURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: unexpected status %s", zipURL, resp.Status)
+	}
+
+	tmp, err := os.CreateTemp("", "glide-gomod-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, sum), resp.Body); err != nil {
+		return err
+	}
+
+	hash := "h1:" + base64.StdEncoding.EncodeToString(sum.Sum(nil))
+	if dep.Hash != "" && hash != dep.Hash {
+		return fmt.Errorf("hash mismatch for %s@%s: expected %s", dep.Name, dep.Reference, dep.Hash)
+	}
+	s.hash = hash
+
+	return unzip(tmp.Name(), dest)
+}
+
+// Resolve reports the version and content hash computed by the prior Fetch.
+// It does not re-fetch the artifact; call Fetch first.
+func (s *goProxySource) Resolve(dep *cfg.Dependency) (string, string, error) {
+	return dep.Reference, s.hash, nil
+}
+
+// tarballSource fetches a plain tarball or zip archive over HTTP(S), keyed
+// off a Repository URL ending in .tar.gz, .tgz or .zip.
+type tarballSource struct {
+	ctx context.Context
+
+	// hash is the content hash computed during Fetch, cached so Resolve
+	// doesn't need to re-download the artifact just to recompute it.
+	hash string
+}
+
+func (s *tarballSource) Fetch(dep *cfg.Dependency, dest string) error {
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodGet, dep.Repository, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching %s: %s", dep.Repository, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: unexpected status %s", dep.Repository, resp.Status)
+	}
+
+	tmp, err := os.CreateTemp("", "glide-tarball-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, sum), resp.Body); err != nil {
+		return err
+	}
+
+	hash := "sha256:" + base64.StdEncoding.EncodeToString(sum.Sum(nil))
+	if dep.Hash != "" && hash != dep.Hash {
+		return fmt.Errorf("hash mismatch for %s: expected %s, got %s", dep.Name, dep.Hash, hash)
+	}
+	s.hash = hash
+
+	repository := strings.ToLower(dep.Repository)
+	switch {
+	case strings.HasSuffix(repository, ".zip"):
+		return unzip(tmp.Name(), dest)
+	case strings.HasSuffix(repository, ".tar.gz"), strings.HasSuffix(repository, ".tgz"):
+		return untargz(tmp.Name(), dest)
+	}
+	return fmt.Errorf("tarball source for %s: only .zip, .tar.gz and .tgz archives are supported", dep.Name)
+}
+
+// Resolve reports the version and content hash computed by the prior Fetch.
+// It does not re-fetch the artifact; call Fetch first.
+func (s *tarballSource) Resolve(dep *cfg.Dependency) (string, string, error) {
+	return dep.Reference, s.hash, nil
+}
+
+// unzip extracts the zip archive at src into dest, creating dest if needed.
+func unzip(src, dest string) error {
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	for _, f := range r.File {
+		path := filepath.Join(dest, f.Name)
+		if !strings.HasPrefix(path, filepath.Clean(dest)+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal file path in archive: %s", f.Name)
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, f.Mode()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+
+		out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+		if err != nil {
+			rc.Close()
+			return err
+		}
+
+		_, err = io.Copy(out, rc)
+		out.Close()
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// untargz extracts the gzip-compressed tar archive at src into dest,
+// creating dest if needed. Entries that would escape dest (e.g. via a "../"
+// path) are rejected, mirroring unzip's protection against zip-slip.
+func untargz(src, dest string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dest, hdr.Name)
+		if !strings.HasPrefix(path, filepath.Clean(dest)+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal file path in archive: %s", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, os.FileMode(hdr.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(hdr.Mode))
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			out.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
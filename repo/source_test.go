@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/glide/cfg"
+)
+
+func TestSourceFor(t *testing.T) {
+	cases := []struct {
+		name       string
+		dep        *cfg.Dependency
+		wantSource Source
+	}{
+		{"explicit goproxy", &cfg.Dependency{SourceType: cfg.SourceGoProxy}, &goProxySource{}},
+		{"explicit tarball", &cfg.Dependency{SourceType: cfg.SourceTarball}, &tarballSource{}},
+		{"explicit vcs", &cfg.Dependency{SourceType: cfg.SourceVCS}, &vcsSource{}},
+		{"auto-detect .zip", &cfg.Dependency{Repository: "https://example.com/pkg.zip"}, &tarballSource{}},
+		{"auto-detect .tar.gz", &cfg.Dependency{Repository: "https://example.com/pkg.tar.gz"}, &tarballSource{}},
+		{"auto-detect fallback to vcs", &cfg.Dependency{Repository: "https://github.com/foo/bar"}, &vcsSource{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := sourceFor(context.Background(), c.dep, "", false, false, false)
+			switch c.wantSource.(type) {
+			case *goProxySource:
+				if _, ok := got.(*goProxySource); !ok {
+					t.Errorf("sourceFor(%+v) = %T, want *goProxySource", c.dep, got)
+				}
+			case *tarballSource:
+				if _, ok := got.(*tarballSource); !ok {
+					t.Errorf("sourceFor(%+v) = %T, want *tarballSource", c.dep, got)
+				}
+			case *vcsSource:
+				if _, ok := got.(*vcsSource); !ok {
+					t.Errorf("sourceFor(%+v) = %T, want *vcsSource", c.dep, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTarballSourceFetchHashMismatch(t *testing.T) {
+	body := []byte("not a real archive, just needs a stable hash")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	dep := &cfg.Dependency{Name: "example", Repository: srv.URL + "/pkg.zip", Hash: "sha256:does-not-match"}
+	src := &tarballSource{ctx: context.Background()}
+
+	err := src.Fetch(dep, t.TempDir())
+	if err == nil {
+		t.Fatal("expected a hash mismatch error, got nil")
+	}
+}
+
+func TestTarballSourceFetchZipSlip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	fw, err := zw.Create("../../escaped.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.Write([]byte("gotcha")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "dest")
+	dep := &cfg.Dependency{Name: "example", Repository: srv.URL + "/pkg.zip"}
+	src := &tarballSource{ctx: context.Background()}
+
+	if err := src.Fetch(dep, dest); err == nil {
+		t.Fatal("expected a zip-slip path to be rejected, got nil error")
+	}
+
+	if _, err := os.Stat(filepath.Join(filepath.Dir(filepath.Dir(dest)), "escaped.txt")); err == nil {
+		t.Fatal("archive entry escaped dest and was written to disk")
+	}
+}
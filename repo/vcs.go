@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/Masterminds/glide/cfg"
+	"github.com/Masterminds/vcs"
+)
+
+// concurrentWorkers is the default cap on in-flight VCS operations when an
+// Installer doesn't set MaxWorkers.
+const concurrentWorkers = 8
+
+// VcsGet clones dep's repository into dest. ctx is checked before the clone
+// begins so a cancelled context (e.g. Ctrl-C) skips dependencies that haven't
+// started yet; the github.com/Masterminds/vcs client used underneath doesn't
+// take a context, so an in-flight clone still runs to completion once started.
+func VcsGet(ctx context.Context, dep *cfg.Dependency, dest, home string, cache, cacheGopath, useGopath bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	repo, err := vcs.NewRepo(dep.Repository, dest)
+	if err != nil {
+		return err
+	}
+	if err := repo.Get(); err != nil {
+		return err
+	}
+	if dep.Reference != "" {
+		return repo.UpdateVersion(dep.Reference)
+	}
+	return nil
+}
+
+// VcsUpdate updates an existing checkout of dep found under cwd. See VcsGet
+// for the ctx cancellation behavior.
+func VcsUpdate(ctx context.Context, dep *cfg.Dependency, cwd string, i *Installer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	dest := filepath.Join(cwd, dep.Name)
+	repo, err := vcs.NewRepo(dep.Repository, dest)
+	if err != nil {
+		return err
+	}
+	if err := repo.Update(); err != nil {
+		return err
+	}
+	if dep.Reference != "" {
+		return repo.UpdateVersion(dep.Reference)
+	}
+	return nil
+}